@@ -0,0 +1,212 @@
+package squarify
+
+import (
+	"math"
+	"sort"
+)
+
+// rtreeBranch is the maximum number of children under an internal rtreeNode.
+const rtreeBranch = 4
+
+// rtreeNode is a node of the R-tree built by NewBlockIndex. A leaf node has index >= 0 and no
+// children; an internal node has index == -1 and one or more children.
+type rtreeNode struct {
+	bounds   Rect
+	index    int
+	children []*rtreeNode
+}
+
+// BlockIndex is a spatial index built on top of the output of Squarify (or SquarifyCushions), which
+// answers point and rectangle queries in roughly O(log n) rather than the linear scan callers would
+// otherwise have to write by hand. It's implemented as a small R-tree over the Block rectangles,
+// bulk-loaded once up front since Squarify's output doesn't change until the caller re-lays out.
+type BlockIndex struct {
+	blocks []Block
+	meta   []Meta
+	root   *rtreeNode
+}
+
+// NewBlockIndex builds a BlockIndex over blocks. meta, if non-nil, must be the Meta slice returned
+// alongside blocks by Squarify so that Locate can prefer deeper (more nested) Blocks; if meta is nil,
+// Locate falls back to preferring the smallest-area match.
+func NewBlockIndex(blocks []Block, meta []Meta) *BlockIndex {
+	idxs := make([]int, len(blocks))
+	for i := range idxs {
+		idxs[i] = i
+	}
+
+	idx := &BlockIndex{blocks: blocks, meta: meta}
+	if len(idxs) > 0 {
+		idx.root = buildRTree(blocks, idxs)
+	}
+	return idx
+}
+
+// buildRTree bulk-loads an R-tree over blocks[idxs]. It repeatedly sorts the remaining indices by
+// their rectangle's X center and splits them into rtreeBranch groups, recursing on each group, which
+// is a simple form of the sort-tile-recursive bulk-loading algorithm.
+func buildRTree(blocks []Block, idxs []int) *rtreeNode {
+	if len(idxs) == 1 {
+		return &rtreeNode{bounds: blocks[idxs[0]].Rect, index: idxs[0]}
+	}
+
+	if len(idxs) <= rtreeBranch {
+		children := make([]*rtreeNode, len(idxs))
+		for i, idx := range idxs {
+			children[i] = &rtreeNode{bounds: blocks[idx].Rect, index: idx}
+		}
+		return &rtreeNode{index: -1, children: children, bounds: unionBounds(children)}
+	}
+
+	sort.Slice(idxs, func(i, j int) bool {
+		a, b := blocks[idxs[i]], blocks[idxs[j]]
+		return a.X+a.W/2 < b.X+b.W/2
+	})
+
+	groupSize := (len(idxs) + rtreeBranch - 1) / rtreeBranch
+	children := make([]*rtreeNode, 0, rtreeBranch)
+	for i := 0; i < len(idxs); i += groupSize {
+		end := i + groupSize
+		if end > len(idxs) {
+			end = len(idxs)
+		}
+		children = append(children, buildRTree(blocks, idxs[i:end]))
+	}
+
+	return &rtreeNode{index: -1, children: children, bounds: unionBounds(children)}
+}
+
+// unionBounds returns the smallest Rect enclosing the bounds of every node in nodes.
+func unionBounds(nodes []*rtreeNode) Rect {
+	r := nodes[0].bounds
+	for _, n := range nodes[1:] {
+		r = unionRect(r, n.bounds)
+	}
+	return r
+}
+
+// unionRect returns the smallest Rect enclosing both a and b.
+func unionRect(a, b Rect) Rect {
+	x1 := math.Min(a.X, b.X)
+	y1 := math.Min(a.Y, b.Y)
+	x2 := math.Max(a.X+a.W, b.X+b.W)
+	y2 := math.Max(a.Y+a.H, b.Y+b.H)
+	return Rect{X: x1, Y: y1, W: x2 - x1, H: y2 - y1}
+}
+
+// rectContainsPoint reports whether (x,y) lies within r, inclusive of its edges.
+func rectContainsPoint(r Rect, x, y float64) bool {
+	return x >= r.X && x <= r.X+r.W && y >= r.Y && y <= r.Y+r.H
+}
+
+// rectsIntersect reports whether a and b overlap.
+func rectsIntersect(a, b Rect) bool {
+	return a.X <= b.X+b.W && a.X+a.W >= b.X && a.Y <= b.Y+b.H && a.Y+a.H >= b.Y
+}
+
+// Locate returns the deepest Block containing the point (x,y), so that nested children are preferred
+// over the ancestors they sit inside. The third return value is false if no Block contains the point.
+func (idx *BlockIndex) Locate(x, y float64) (Block, Meta, bool) {
+	best := -1
+	idx.forEachContaining(idx.root, x, y, func(i int) {
+		if best == -1 {
+			best = i
+			return
+		}
+		if idx.deeper(i, best) {
+			best = i
+		}
+	})
+
+	if best == -1 {
+		return Block{}, Meta{}, false
+	}
+
+	var m Meta
+	if idx.meta != nil {
+		m = idx.meta[best]
+	}
+	return idx.blocks[best], m, true
+}
+
+// deeper reports whether block i should be preferred over block j when both contain the query point:
+// by greater Meta.Depth if meta is available, otherwise by smaller area.
+func (idx *BlockIndex) deeper(i, j int) bool {
+	if idx.meta != nil {
+		return idx.meta[i].Depth > idx.meta[j].Depth
+	}
+	bi, bj := idx.blocks[i], idx.blocks[j]
+	return bi.W*bi.H < bj.W*bj.H
+}
+
+// forEachContaining calls f with the index of every Block under node whose rectangle contains (x,y).
+func (idx *BlockIndex) forEachContaining(node *rtreeNode, x, y float64, f func(i int)) {
+	if node == nil || !rectContainsPoint(node.bounds, x, y) {
+		return
+	}
+	if node.index >= 0 {
+		f(node.index)
+		return
+	}
+	for _, c := range node.children {
+		idx.forEachContaining(c, x, y, f)
+	}
+}
+
+// Search returns the indices, into the blocks slice passed to NewBlockIndex, of every Block that
+// intersects r.
+func (idx *BlockIndex) Search(r Rect) []int {
+	var out []int
+	idx.forEachIntersecting(idx.root, r, func(i int) {
+		out = append(out, i)
+	})
+	return out
+}
+
+// forEachIntersecting calls f with the index of every Block under node that intersects r.
+func (idx *BlockIndex) forEachIntersecting(node *rtreeNode, r Rect, f func(i int)) {
+	if node == nil || !rectsIntersect(node.bounds, r) {
+		return
+	}
+	if node.index >= 0 {
+		f(node.index)
+		return
+	}
+	for _, c := range node.children {
+		idx.forEachIntersecting(c, r, f)
+	}
+}
+
+// KNearest returns the indices, ordered nearest first, of the k Blocks whose centers are closest to
+// (x,y). If fewer than k Blocks were indexed, all of them are returned. A negative k is treated as 0.
+func (idx *BlockIndex) KNearest(x, y float64, k int) []int {
+	if k <= 0 {
+		return nil
+	}
+
+	type candidate struct {
+		index    int
+		distance float64
+	}
+
+	candidates := make([]candidate, len(idx.blocks))
+	for i, b := range idx.blocks {
+		cx, cy := b.X+b.W/2, b.Y+b.H/2
+		dx, dy := cx-x, cy-y
+		candidates[i] = candidate{index: i, distance: dx*dx + dy*dy}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	if k > len(candidates) {
+		k = len(candidates)
+	}
+
+	out := make([]int, k)
+	for i := 0; i < k; i++ {
+		out[i] = candidates[i].index
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package squarify
+
+import "testing"
+
+func TestBlockIndexLocatePrefersDeepest(t *testing.T) {
+	outer := &TestNode{name: "outer"}
+	inner := &TestNode{name: "inner"}
+
+	blocks := []Block{
+		{Rect: Rect{X: 0, Y: 0, W: 100, H: 100}, TreeSizer: outer},
+		{Rect: Rect{X: 10, Y: 10, W: 20, H: 20}, TreeSizer: inner},
+	}
+	meta := []Meta{{Depth: 0}, {Depth: 1}}
+
+	idx := NewBlockIndex(blocks, meta)
+
+	blk, m, ok := idx.Locate(15, 15)
+	if !ok {
+		t.Fatal("Locate found nothing at a point inside both blocks")
+	}
+	if blk.TreeSizer.(*TestNode) != inner {
+		t.Fatal("Locate returned the outer block instead of the nested inner block")
+	}
+	if m.Depth != 1 {
+		t.Fatal("Locate returned Meta for the wrong block")
+	}
+
+	blk, _, ok = idx.Locate(90, 90)
+	if !ok || blk.TreeSizer.(*TestNode) != outer {
+		t.Fatal("Locate should have found only the outer block at a point outside the inner one")
+	}
+
+	_, _, ok = idx.Locate(-5, -5)
+	if ok {
+		t.Fatal("Locate should have found nothing outside every block")
+	}
+}
+
+func TestBlockIndexSearch(t *testing.T) {
+	blocks := []Block{
+		{Rect: Rect{X: 0, Y: 0, W: 10, H: 10}, TreeSizer: &TestNode{name: "a"}},
+		{Rect: Rect{X: 20, Y: 0, W: 10, H: 10}, TreeSizer: &TestNode{name: "b"}},
+		{Rect: Rect{X: 40, Y: 0, W: 10, H: 10}, TreeSizer: &TestNode{name: "c"}},
+	}
+
+	idx := NewBlockIndex(blocks, nil)
+
+	got := idx.Search(Rect{X: 15, Y: 0, W: 20, H: 10})
+	if len(got) != 1 || got[0] != 1 {
+		t.Fatalf("Search returned %v, wanted [1]", got)
+	}
+
+	got = idx.Search(Rect{X: 0, Y: 0, W: 100, H: 10})
+	if len(got) != 3 {
+		t.Fatalf("Search returned %v, wanted all 3 blocks", got)
+	}
+}
+
+func TestBlockIndexKNearest(t *testing.T) {
+	blocks := []Block{
+		{Rect: Rect{X: 0, Y: 0, W: 10, H: 10}, TreeSizer: &TestNode{name: "a"}},
+		{Rect: Rect{X: 20, Y: 0, W: 10, H: 10}, TreeSizer: &TestNode{name: "b"}},
+		{Rect: Rect{X: 40, Y: 0, W: 10, H: 10}, TreeSizer: &TestNode{name: "c"}},
+	}
+
+	idx := NewBlockIndex(blocks, nil)
+
+	got := idx.KNearest(26, 5, 2)
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("KNearest(26, 5, 2) returned %v, wanted [1 2]", got)
+	}
+
+	got = idx.KNearest(0, 0, 10)
+	if len(got) != 3 {
+		t.Fatalf("KNearest with k larger than the number of blocks returned %v, wanted all 3", got)
+	}
+
+	got = idx.KNearest(0, 0, -1)
+	if len(got) != 0 {
+		t.Fatalf("KNearest with a negative k returned %v, wanted none", got)
+	}
+}
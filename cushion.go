@@ -0,0 +1,136 @@
+package squarify
+
+import (
+	"math"
+)
+
+// Cushion holds the per-block shading coefficients produced by SquarifyCushions, following the
+// parabolic height-field technique used to render "cushion treemaps" (van Wijk and van de Wetering,
+// "Cushion Treemaps"). At any point (x,y) inside the block's rectangle, the surface normal of the cushion is
+//
+//	nx = 2*SX2*x + SX1
+//	ny = 2*SY2*y + SY1
+//
+// Pass that normal, together with a Light, to Shade to get a Lambertian shading intensity suitable
+// for filling the rectangle.
+type Cushion struct {
+	SX1, SX2, SY1, SY2 float64
+}
+
+// Light is a directional light used by Shade.
+type Light struct {
+	X, Y, Z float64
+}
+
+// DefaultLight is the light direction traditionally used when rendering cushion treemaps.
+var DefaultLight = Light{X: 0.09759, Y: 0.09759, Z: 0.99045}
+
+// CushionOptions controls the shape of the cushions produced by SquarifyCushions.
+type CushionOptions struct {
+	// H is the height of the parabolic bump added at the shallowest nesting level. Decays by F at
+	// each level below that. If left as the zero value, 0.5 is used.
+	H float64
+	// F is the decay factor applied to H per level of nesting. If left as the zero value, 0.75 is
+	// used.
+	F float64
+}
+
+// SquarifyCushions lays out root exactly as Squarify does, and additionally returns a Cushion for
+// each Block describing its shading. cushions[i] is the Cushion for blocks[i]. A Block's Cushion
+// accumulates the parabolic bump contributed by the block's own rectangle on top of the bumps
+// contributed by all of its ancestors, so that nested children sit "inside" their parent's cushion
+// the way van Wijk's technique expects.
+func SquarifyCushions(root TreeSizer, rect Rect, options Options, cushionOpts CushionOptions) (blocks []Block, meta []Meta, cushions []Cushion) {
+	if options.MaxDepth <= 0 {
+		options.MaxDepth = 20
+	}
+	if cushionOpts.H == 0 {
+		cushionOpts.H = 0.5
+	}
+	if cushionOpts.F == 0 {
+		cushionOpts.F = 0.75
+	}
+
+	return squarifyCushions(root, Block{Rect: rect}, options, cushionOpts, 0, Cushion{})
+}
+
+// squarifyCushions is the internal recursive driver behind SquarifyCushions. It mirrors squarify,
+// but threads the accumulated parent Cushion down through the recursion.
+func squarifyCushions(root TreeSizer, block Block, options Options, cushionOpts CushionOptions, depth int, parent Cushion) (blocks []Block, meta []Meta, cushions []Cushion) {
+	blocks = make([]Block, 0)
+	meta = make([]Meta, 0)
+	cushions = make([]Cushion, 0)
+
+	if block.W <= options.MinW || block.H <= options.MinH || depth >= options.MaxDepth {
+		return
+	}
+
+	h := cushionOpts.H * math.Pow(cushionOpts.F, float64(depth))
+
+	areas := areas(root, block, options)
+	layout := layoutFor(options.Algorithm, depth)
+
+	for _, b := range layout.LayOut(areas, block.Rect) {
+		// Filter out any blocks that are just placeholders for extra space, or too small.
+		if b.TreeSizer == nil || (b.W <= options.MinW && b.H <= options.MinH) {
+			continue
+		}
+
+		blocks = append(blocks, b)
+		meta = append(meta, Meta{Depth: depth})
+		cushions = append(cushions, addRidge(parent, b.Rect, h))
+	}
+
+	// Now lay out the children of each of the blocks we just processed inside their parent box.
+	for i, b := range blocks {
+		if options.Margins != nil {
+			b.X += options.Margins.L
+			b.Y += options.Margins.T
+			b.W -= options.Margins.L + options.Margins.R
+			b.H -= options.Margins.T + options.Margins.B
+		}
+
+		newBlocks, newMeta, newCushions := squarifyCushions(b.TreeSizer, b, options, cushionOpts, depth+1, cushions[i])
+		blocks = append(blocks, newBlocks...)
+		meta = append(meta, newMeta...)
+		cushions = append(cushions, newCushions...)
+	}
+
+	return
+}
+
+// addRidge returns the Cushion produced by adding rect's own parabolic height bump, of height h, on
+// top of the bumps already accumulated in parent.
+func addRidge(parent Cushion, rect Rect, h float64) Cushion {
+	c := parent
+	if rect.W > 0 {
+		c.SX1 += 4 * h / rect.W
+		c.SX2 += -4 * h / (rect.W * rect.W)
+	}
+	if rect.H > 0 {
+		c.SY1 += 4 * h / rect.H
+		c.SY2 += -4 * h / (rect.H * rect.H)
+	}
+	return c
+}
+
+// Shade computes the Lambertian shading intensity, in the range [0,1], at the point (x,y) using
+// cushion's accumulated coefficients and a light direction. Renderers call this once per pixel (or
+// once per small tile, for speed) to produce the 3D-looking fill of a cushion treemap.
+func Shade(cushion Cushion, x, y float64, light Light) float64 {
+	nx := 2*cushion.SX2*x + cushion.SX1
+	ny := 2*cushion.SY2*y + cushion.SY1
+	nz := float64(1)
+
+	lightLength := math.Sqrt(light.X*light.X + light.Y*light.Y + light.Z*light.Z)
+	if lightLength == 0 {
+		return 0
+	}
+
+	length := math.Sqrt(nx*nx + ny*ny + nz*nz)
+	cosAngle := (nx*light.X + ny*light.Y + nz*light.Z) / (length * lightLength)
+	if cosAngle < 0 {
+		cosAngle = 0
+	}
+	return cosAngle
+}
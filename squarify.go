@@ -9,6 +9,7 @@
 package squarify
 
 import (
+	"math"
 	"sort"
 )
 
@@ -75,6 +76,75 @@ type Meta struct {
 	Depth int
 }
 
+// Algorithm selects which layout algorithm Squarify() uses to arrange the children of a node within
+// their parent's rectangle.
+type Algorithm int
+
+const (
+	// Squarified is the algorithm of Bruls, Huizing, and Van Wijk. It keeps children in the order it
+	// is given them (any sorting is done beforehand, per Options.SortMode) and chooses where to break
+	// rows so that rectangles stay close to square. This is the default and zero value of Algorithm.
+	Squarified Algorithm = iota
+	// SliceAndDice alternates horizontal and vertical splits at each depth level, proportional to
+	// Size, and never reorders children. Rectangles tend to be thin, but the layout is simple and
+	// stable to understand.
+	SliceAndDice
+	// Strip fills rows greedily like Squarified, but never reorders items: a row is committed once
+	// adding the next item would worsen the row's mean aspect ratio.
+	Strip
+	// PivotByMiddle is an Ordered Treemap algorithm that never reorders children. The pivot is the
+	// child that splits the cumulative Size of the list roughly in half.
+	PivotByMiddle
+	// PivotBySize is an Ordered Treemap algorithm that never reorders children. The pivot is the
+	// largest child in the current sub-list.
+	PivotBySize
+)
+
+// Layout arranges a set of areas, already sized proportional to rect, into concrete Blocks within
+// rect. Implementations must preserve the total area of children but are free to decide how (and
+// whether) to reorder them. A child with a nil TreeSizer is a placeholder for unaccounted space and
+// must still be given a Block so that it occupies room in the layout, even though Squarify() discards
+// it afterwards.
+type Layout interface {
+	LayOut(children []area, rect Rect) []Block
+}
+
+// layoutFor returns the Layout implementation selected by algo. depth is passed through so that
+// direction-alternating algorithms like SliceAndDice can vary their split direction per level.
+func layoutFor(algo Algorithm, depth int) Layout {
+	switch algo {
+	case SliceAndDice:
+		dir := Horizontal
+		if depth%2 == 1 {
+			dir = Vertical
+		}
+		return sliceAndDiceLayout{dir: dir}
+	case Strip:
+		return stripLayout{}
+	case PivotByMiddle:
+		return pivotLayout{byMiddle: true}
+	case PivotBySize:
+		return pivotLayout{byMiddle: false}
+	default:
+		return squarifiedLayout{}
+	}
+}
+
+// SortMode selects how children are ordered within their parent before being handed to a Layout.
+type SortMode int
+
+const (
+	// SortNone lays out children in their original order.
+	SortNone SortMode = iota
+	// SortSizeDescending sorts children from largest Size to smallest. This pushes larger blocks to
+	// the left/above smaller blocks.
+	SortSizeDescending
+	// SortSizeAscending sorts children from smallest Size to largest.
+	SortSizeAscending
+	// SortCustom sorts children using Options.Less.
+	SortCustom
+)
+
 // Options controls how Squarify() behaves.
 type Options struct {
 	// Maximum depth in the tree to descend to. Blocks at depth <= MaxDepth are layed out,
@@ -85,10 +155,28 @@ type Options struct {
 	Margins *Margins
 	// Sort the blocks by size within their parent. This pushes larger blocks to the left/above
 	// smaller blocks.
+	//
+	// Deprecated: use SortMode instead. Sort is still honored if SortMode is left at its zero value,
+	// SortNone: Sort == true behaves like SortMode == SortSizeDescending.
 	Sort bool
+	// SortMode controls how children are ordered within their parent before being laid out. The zero
+	// value, SortNone, falls back to the deprecated Sort bool for compatibility.
+	SortMode SortMode
+	// Less is the comparator used when SortMode is SortCustom. It should report whether a should sort
+	// before b; the placeholder for unaccounted space always sorts last regardless of what Less
+	// reports for it. If SortMode is SortCustom and Less is nil, children are left in their original
+	// order.
+	Less func(a, b TreeSizer) bool
 	// MinW and MinH limit the smallness of Blocks that are output. Blocks who's width is < MinW
 	// or who's height is < MinH are not output, nor are their children processed.
 	MinW, MinH float64
+	// Algorithm selects the layout algorithm used to arrange children within their parent. The zero
+	// value, Squarified, is the original Squarified Treemap algorithm.
+	Algorithm Algorithm
+	// FocusPath, if non-empty, renders the descendant it names (a sequence of child indices, one per
+	// level) with the maximum area allocation regardless of its siblings' Size, for "focus+context"
+	// views. It's consumed by View.Render; Squarify itself ignores it.
+	FocusPath []int
 }
 
 // Squarify implements the Squarified Treemap algorithm. It lays out the children of root inside the area
@@ -213,6 +301,22 @@ func (r *row) worst() float64 {
 	return worst2
 }
 
+// meanAspect returns the mean aspect ratio (long side / short side) of the rectangles that would
+// result from laying out the row's current contents. Used by stripLayout, which commits a row based
+// on its mean aspect ratio rather than its worst one.
+func (r *row) meanAspect() float64 {
+	_, blocks := r.makeBlocks()
+	if len(blocks) == 0 {
+		return 0
+	}
+
+	sum := float64(0)
+	for _, b := range blocks {
+		sum += aspectRatio(b.W, b.H)
+	}
+	return sum / float64(len(blocks))
+}
+
 // makeBlocks creates the final slice of blocks for the row.
 func (r *row) makeBlocks() (height float64, blocks []Block) {
 	if r.min == 0 {
@@ -275,15 +379,137 @@ func squarify(root TreeSizer, block Block, options Options, depth int) (blocks [
 		}
 	}
 
-	areas := areas(root, block, options.Sort)
+	areas := areas(root, block, options)
+
+	layout := layoutFor(options.Algorithm, depth)
+	output(layout.LayOut(areas, block.Rect))
+
+	// Now, for each of the items we just processed, if they have children then
+	// lay them out inside their parent box. The available area may be reduced by
+	// certain size.
+	for _, block := range blocks {
+		if block.TreeSizer != nil {
+			if options.Margins != nil {
+				block.X += options.Margins.L
+				block.Y += options.Margins.T
+				block.W -= options.Margins.L + options.Margins.R
+				block.H -= options.Margins.T + options.Margins.B
+			}
+
+			newBlocks, newMeta := squarify(block.TreeSizer, block, options, depth+1)
+			blocks = append(blocks, newBlocks...)
+			meta = append(meta, newMeta...)
+		}
+	}
+
+	return
+}
+
+// byAreaAndPlaceholder sorts a slice of areas according to a SortMode (or a custom Less, for
+// SortCustom). Whatever the mode, the placeholder area (TreeSizer == nil), if present, always sorts
+// last: it represents unaccounted space and should never be placed ahead of a real child.
+type byAreaAndPlaceholder struct {
+	areas []area
+	mode  SortMode
+	less  func(a, b TreeSizer) bool
+}
+
+func (s byAreaAndPlaceholder) Len() int {
+	return len(s.areas)
+}
 
-	rowX := block.X
-	rowY := block.Y
-	freeWidth := block.W
-	freeHeight := block.H
+func (s byAreaAndPlaceholder) Less(i, j int) bool {
+	a, b := s.areas[i], s.areas[j]
+
+	if (a.TreeSizer == nil) != (b.TreeSizer == nil) {
+		return a.TreeSizer != nil
+	}
+	if a.TreeSizer == nil {
+		return false
+	}
+
+	switch s.mode {
+	case SortSizeAscending:
+		return a.Area < b.Area
+	case SortCustom:
+		if s.less == nil {
+			// No comparator was supplied; fall back to the original order rather than panicking.
+			return false
+		}
+		return s.less(a.TreeSizer, b.TreeSizer)
+	default:
+		return a.Area > b.Area
+	}
+}
+
+func (s byAreaAndPlaceholder) Swap(i, j int) {
+	s.areas[i], s.areas[j] = s.areas[j], s.areas[i]
+}
+
+// effectiveSortMode resolves the SortMode that areas should use, honoring the deprecated Sort bool
+// for callers who haven't migrated to SortMode yet.
+func effectiveSortMode(options Options) SortMode {
+	if options.SortMode != SortNone {
+		return options.SortMode
+	}
+	if options.Sort {
+		return SortSizeDescending
+	}
+	return SortNone
+}
+
+// areas computes the areas for the children of `root` and sorts them according to options' sort
+// mode. The area of a child is calculated as it's proportion of the parent's Block's size, where
+// `root` is the parent, and `Block` is the dimensions of the parent.
+func areas(root TreeSizer, block Block, options Options) (areas []area) {
+	blockArea := block.W * block.H
+
+	areas = make([]area, 0)
+	itemsTotalSize := float64(0)
+
+	for i := 0; i < root.NumChildren(); i++ {
+		item := root.Child(i)
+
+		// Ignore 0-size items
+		if item.Size() <= 0 {
+			continue
+		}
+
+		areas = append(areas, area{Area: item.Size() / root.Size() * blockArea, TreeSizer: item})
+		itemsTotalSize += item.Size()
+	}
+
+	// Add a placeholder area for extra space
+	if itemsTotalSize < root.Size() {
+		a := (root.Size() - itemsTotalSize) / root.Size() * blockArea
+		areas = append(areas, area{Area: a, TreeSizer: nil})
+	}
+
+	mode := effectiveSortMode(options)
+	if mode != SortNone {
+		sort.Sort(byAreaAndPlaceholder{areas: areas, mode: mode, less: options.Less})
+	}
+
+	return
+}
+
+// squarifiedLayout implements the Squarified Treemap algorithm: it greedily fills a row and commits
+// the row once adding the next item would make the row's worst aspect ratio worse. This is the same
+// algorithm Squarify() has always used; it was pulled out into a Layout so that it can be swapped for
+// the other algorithms below.
+type squarifiedLayout struct{}
+
+// LayOut implements Layout.
+func (squarifiedLayout) LayOut(children []area, rect Rect) []Block {
+	blocks := make([]Block, 0, len(children))
+
+	rowX := rect.X
+	rowY := rect.Y
+	freeWidth := rect.W
+	freeHeight := rect.H
 
 	makeRow := func() (row *row) {
-		if block.W > block.H {
+		if rect.W > rect.H {
 			row = newRow(Vertical, freeHeight, rowX, rowY)
 		} else {
 			row = newRow(Horizontal, freeWidth, rowX, rowY)
@@ -294,7 +520,7 @@ func squarify(root TreeSizer, block Block, options Options, depth int) (blocks [
 	// Decide which direction to create the new row
 	row := makeRow()
 
-	for _, a := range areas {
+	for _, a := range children {
 		if row.size() > 0 {
 			worstBefore := row.worst()
 			worstAfter := float64(0)
@@ -306,7 +532,7 @@ func squarify(root TreeSizer, block Block, options Options, depth int) (blocks [
 				// It's better to make a new row now.
 				// Output the current blocks and make a new row
 				offset, newBlocks := row.makeBlocks()
-				output(newBlocks)
+				blocks = append(blocks, newBlocks...)
 
 				if row.Dir == Vertical {
 					rowX += offset
@@ -327,80 +553,265 @@ func squarify(root TreeSizer, block Block, options Options, depth int) (blocks [
 
 	if row.size() > 0 {
 		_, newBlocks := row.makeBlocks()
-		output(newBlocks)
+		blocks = append(blocks, newBlocks...)
 	}
 
-	// Now, for each of the items we just processed, if they have children then
-	// lay them out inside their parent box. The available area may be reduced by
-	// certain size.
-	for _, block := range blocks {
-		if block.TreeSizer != nil {
-			if options.Margins != nil {
-				block.X += options.Margins.L
-				block.Y += options.Margins.T
-				block.W -= options.Margins.L + options.Margins.R
-				block.H -= options.Margins.T + options.Margins.B
-			}
+	return blocks
+}
 
-			newBlocks, newMeta := squarify(block.TreeSizer, block, options, depth+1)
-			blocks = append(blocks, newBlocks...)
-			meta = append(meta, newMeta...)
+// sliceAndDiceLayout implements the classic Slice-and-Dice algorithm: children are split along a
+// single direction, proportional to their Size, in their existing order. Squarify() alternates dir
+// with depth so that nested levels slice perpendicular to their parent.
+type sliceAndDiceLayout struct {
+	dir direction
+}
+
+// LayOut implements Layout.
+func (l sliceAndDiceLayout) LayOut(children []area, rect Rect) []Block {
+	blocks := make([]Block, 0, len(children))
+
+	total := sumAreas(children)
+	if total <= 0 {
+		return blocks
+	}
+
+	x, y := rect.X, rect.Y
+	for _, a := range children {
+		frac := a.Area / total
+		var b Block
+		if l.dir == Horizontal {
+			w := frac * rect.W
+			b = Block{Rect: Rect{X: x, Y: y, W: w, H: rect.H}, TreeSizer: a.TreeSizer}
+			x += w
+		} else {
+			h := frac * rect.H
+			b = Block{Rect: Rect{X: x, Y: y, W: rect.W, H: h}, TreeSizer: a.TreeSizer}
+			y += h
 		}
+		blocks = append(blocks, b)
 	}
 
-	return
+	return blocks
 }
 
-// Sort areas by area.
-type byAreaAndPlaceholder []area
+// stripLayout implements the Strip Treemap algorithm: like Squarified it fills rows greedily, but it
+// never reorders items. A strip is committed once adding the next item would worsen the strip's mean
+// aspect ratio, rather than its worst aspect ratio.
+type stripLayout struct{}
 
-func (a byAreaAndPlaceholder) Len() int {
-	return len(a)
-}
+// LayOut implements Layout.
+func (stripLayout) LayOut(children []area, rect Rect) []Block {
+	blocks := make([]Block, 0, len(children))
+
+	rowX := rect.X
+	rowY := rect.Y
+	freeWidth := rect.W
+	freeHeight := rect.H
+
+	makeRow := func() (row *row) {
+		if rect.W > rect.H {
+			row = newRow(Vertical, freeHeight, rowX, rowY)
+		} else {
+			row = newRow(Horizontal, freeWidth, rowX, rowY)
+		}
+		return row
+	}
+
+	row := makeRow()
+
+	for _, a := range children {
+		if row.size() > 0 {
+			meanBefore := row.meanAspect()
+			meanAfter := float64(0)
+			row.pushTemporarily(&a, func() {
+				meanAfter = row.meanAspect()
+			})
+
+			if meanBefore < meanAfter {
+				offset, newBlocks := row.makeBlocks()
+				blocks = append(blocks, newBlocks...)
+
+				if row.Dir == Vertical {
+					rowX += offset
+					freeWidth -= offset
+				} else {
+					rowY += offset
+					freeHeight -= offset
+				}
+
+				row = makeRow()
+			}
+		}
 
-func (a byAreaAndPlaceholder) Less(i, j int) bool {
+		cp := &area{}
+		*cp = a
+		row.push(cp)
+	}
 
-	if a[i].TreeSizer != nil && a[j].TreeSizer != nil || a[i].TreeSizer == nil && a[j].TreeSizer == nil {
-		return a[i].Area > a[j].Area
+	if row.size() > 0 {
+		_, newBlocks := row.makeBlocks()
+		blocks = append(blocks, newBlocks...)
 	}
 
-	return a[i].TreeSizer != nil
+	return blocks
 }
 
-func (a byAreaAndPlaceholder) Swap(i, j int) {
-	a[i], a[j] = a[j], a[i]
+// pivotLayout implements the Ordered Treemap "pivot" algorithms described by Bederson, Shneiderman,
+// and Wattenberg. It never reorders children: a pivot is chosen from the current sub-list, its
+// rectangle is placed along the longer side of rect, and the remaining children are partitioned into
+// L1 (before the pivot), L2 (children after the pivot, grown one at a time for as long as doing so
+// keeps the pivot's rectangle closer to square), and L3 (everything left over). Each partition is laid
+// out recursively in its own sub-rectangle, preserving the original order throughout.
+type pivotLayout struct {
+	// byMiddle selects Pivot-by-Middle (the pivot is the item that splits the cumulative area roughly
+	// in half) instead of Pivot-by-Size (the pivot is the largest item).
+	byMiddle bool
 }
 
-// areas computes the areas for the children of `root` and sorts them if dosort is true.
-// The area of a child is calculated as it's proportion of the parent's Block's size, where
-// `root` is the parent, and `Block` is the dimensions of the parent.
-func areas(root TreeSizer, block Block, dosort bool) (areas []area) {
-	blockArea := block.W * block.H
+// pivotIndex chooses which child of children is the pivot.
+func (l pivotLayout) pivotIndex(children []area) int {
+	if l.byMiddle {
+		total := sumAreas(children)
+		half := total / 2
+		running := float64(0)
+		for i, a := range children {
+			running += a.Area
+			if running >= half {
+				return i
+			}
+		}
+		return len(children) - 1
+	}
 
-	areas = make([]area, 0)
-	itemsTotalSize := float64(0)
+	best := 0
+	for i, a := range children {
+		if a.Area > children[best].Area {
+			best = i
+		}
+	}
+	return best
+}
 
-	for i := 0; i < root.NumChildren(); i++ {
-		item := root.Child(i)
+// LayOut implements Layout.
+func (l pivotLayout) LayOut(children []area, rect Rect) []Block {
+	if len(children) == 0 {
+		return nil
+	}
+	if len(children) == 1 {
+		return []Block{{Rect: rect, TreeSizer: children[0].TreeSizer}}
+	}
 
-		// Ignore 0-size items
-		if item.Size() <= 0 {
-			continue
-		}
+	total := sumAreas(children)
+	if total <= 0 {
+		return nil
+	}
 
-		areas = append(areas, area{Area: item.Size() / root.Size() * blockArea, TreeSizer: item})
-		itemsTotalSize += item.Size()
+	pivotIdx := l.pivotIndex(children)
+	before := children[:pivotIdx]
+	pivot := children[pivotIdx]
+	rest := children[pivotIdx+1:]
+	horizontal := rect.W >= rect.H
+
+	// Grow L2 one item at a time for as long as doing so keeps the pivot's rectangle closer to
+	// square; everything past that point falls into L3.
+	l2End := 0
+	bestAspect := math.Inf(1)
+	for n := 0; n <= len(rest); n++ {
+		bandTotal := sumAreas(before) + pivot.Area + sumAreas(rest[:n])
+		bandFrac := bandTotal / total
+		var bandW, bandH float64
+		if horizontal {
+			bandW, bandH = rect.W*bandFrac, rect.H
+		} else {
+			bandW, bandH = rect.W, rect.H*bandFrac
+		}
+		pivotFrac := pivot.Area / bandTotal
+		var pw, ph float64
+		if horizontal {
+			pw, ph = bandW, bandH*pivotFrac
+		} else {
+			pw, ph = bandW*pivotFrac, bandH
+		}
+		aspect := aspectRatio(pw, ph)
+		if aspect < bestAspect {
+			bestAspect = aspect
+			l2End = n
+		} else {
+			break
+		}
+	}
+	l2 := rest[:l2End]
+	l3 := rest[l2End:]
+
+	bandTotal := sumAreas(before) + pivot.Area + sumAreas(l2)
+	bandFrac := bandTotal / total
+
+	var band, l3Rect Rect
+	if horizontal {
+		band = Rect{X: rect.X, Y: rect.Y, W: rect.W * bandFrac, H: rect.H}
+		l3Rect = Rect{X: rect.X + band.W, Y: rect.Y, W: rect.W - band.W, H: rect.H}
+	} else {
+		band = Rect{X: rect.X, Y: rect.Y, W: rect.W, H: rect.H * bandFrac}
+		l3Rect = Rect{X: rect.X, Y: rect.Y + band.H, W: rect.W, H: rect.H - band.H}
 	}
 
-	// Add a placeholder area for extra space
-	if itemsTotalSize < root.Size() {
-		a := (root.Size() - itemsTotalSize) / root.Size() * blockArea
-		areas = append(areas, area{Area: a, TreeSizer: nil})
+	// Within the band, L1, the pivot, and L2 are stacked perpendicular to the band's long axis so
+	// the pivot keeps the order position it had in the input.
+	beforeFrac := sumAreas(before) / bandTotal
+	pivotFrac := pivot.Area / bandTotal
+
+	blocks := make([]Block, 0, len(children))
+
+	if horizontal {
+		y := band.Y
+		beforeH := band.H * beforeFrac
+		l1Rect := Rect{X: band.X, Y: y, W: band.W, H: beforeH}
+		y += beforeH
+		pivotH := band.H * pivotFrac
+		pivotRect := Rect{X: band.X, Y: y, W: band.W, H: pivotH}
+		y += pivotH
+		l2Rect := Rect{X: band.X, Y: y, W: band.W, H: band.H - beforeH - pivotH}
+
+		blocks = append(blocks, l.LayOut(before, l1Rect)...)
+		blocks = append(blocks, Block{Rect: pivotRect, TreeSizer: pivot.TreeSizer})
+		blocks = append(blocks, l.LayOut(l2, l2Rect)...)
+	} else {
+		x := band.X
+		beforeW := band.W * beforeFrac
+		l1Rect := Rect{X: x, Y: band.Y, W: beforeW, H: band.H}
+		x += beforeW
+		pivotW := band.W * pivotFrac
+		pivotRect := Rect{X: x, Y: band.Y, W: pivotW, H: band.H}
+		x += pivotW
+		l2Rect := Rect{X: x, Y: band.Y, W: band.W - beforeW - pivotW, H: band.H}
+
+		blocks = append(blocks, l.LayOut(before, l1Rect)...)
+		blocks = append(blocks, Block{Rect: pivotRect, TreeSizer: pivot.TreeSizer})
+		blocks = append(blocks, l.LayOut(l2, l2Rect)...)
 	}
 
-	if dosort {
-		sort.Sort(byAreaAndPlaceholder(areas))
+	blocks = append(blocks, l.LayOut(l3, l3Rect)...)
+
+	return blocks
+}
+
+// sumAreas returns the sum of Area over areas.
+func sumAreas(areas []area) float64 {
+	sum := float64(0)
+	for _, a := range areas {
+		sum += a.Area
 	}
+	return sum
+}
 
-	return
+// aspectRatio returns the ratio of the longer of w, h to the shorter. It is +Inf if either dimension
+// is zero or negative.
+func aspectRatio(w, h float64) float64 {
+	if w <= 0 || h <= 0 {
+		return math.Inf(1)
+	}
+	if w > h {
+		return w / h
+	}
+	return h / w
 }
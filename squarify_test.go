@@ -1,6 +1,7 @@
 package squarify
 
 import (
+	"math"
 	"testing"
 )
 
@@ -158,3 +159,286 @@ func TestMaxDepth(t *testing.T) {
 	}
 
 }
+
+// totalDelta sums, over every TreeSizer present in both slices, the distance its Block moved between
+// the two layouts. TreeSizers absent from either slice are ignored.
+func totalDelta(before, after []Block) float64 {
+	prev := map[TreeSizer]Rect{}
+	for _, b := range before {
+		if b.TreeSizer != nil {
+			prev[b.TreeSizer] = b.Rect
+		}
+	}
+
+	delta := float64(0)
+	for _, b := range after {
+		if b.TreeSizer == nil {
+			continue
+		}
+		if p, ok := prev[b.TreeSizer]; ok {
+			dx := b.X - p.X
+			dy := b.Y - p.Y
+			delta += math.Abs(dx) + math.Abs(dy)
+		}
+	}
+	return delta
+}
+
+// TestPivotBySizeStability verifies that PivotBySize keeps Block positions far more stable than
+// Squarified+DoSort does when the underlying tree changes between calls. The scenario grows "d" enough
+// that it overtakes "c" in size: Squarified+DoSort re-sorts and visibly swaps their row position, while
+// PivotBySize never reorders children so the layout only shifts locally.
+func TestPivotBySizeStability(t *testing.T) {
+	nodes := []*TestNode{
+		{name: "a", size: 40},
+		{name: "b", size: 30},
+		{name: "c", size: 20},
+		{name: "d", size: 15},
+		{name: "e", size: 10},
+		{name: "f", size: 8},
+	}
+
+	makeRoot := func() TestNode {
+		total := float64(0)
+		for _, n := range nodes {
+			total += n.size
+		}
+		return TestNode{name: "root", children: nodes, size: total}
+	}
+
+	canvas := Rect{X: 0, Y: 0, W: 100, H: 100}
+
+	orderedBefore, _ := Squarify(makeRoot(), canvas, Options{Algorithm: PivotBySize})
+	squarifiedBefore, _ := Squarify(makeRoot(), canvas, Options{Sort: DoSort})
+
+	// "d" grows from 15 to 25, overtaking "c" (20) in size.
+	nodes[3].size = 25
+
+	orderedAfter, _ := Squarify(makeRoot(), canvas, Options{Algorithm: PivotBySize})
+	squarifiedAfter, _ := Squarify(makeRoot(), canvas, Options{Sort: DoSort})
+
+	orderedDelta := totalDelta(orderedBefore, orderedAfter)
+	squarifiedDelta := totalDelta(squarifiedBefore, squarifiedAfter)
+
+	if orderedDelta >= squarifiedDelta {
+		t.Fatalf("PivotBySize was not more stable than Squarified+DoSort: ordered delta %v, squarified delta %v", orderedDelta, squarifiedDelta)
+	}
+}
+
+// approxEqual reports whether a and b are within eps of each other, to tolerate floating-point
+// rounding when comparing computed geometry.
+func approxEqual(a, b, eps float64) bool {
+	return math.Abs(a-b) <= eps
+}
+
+func TestSliceAndDiceProportionalAndAlternatesDirection(t *testing.T) {
+	a1 := &TestNode{name: "a1", size: 40}
+	a2 := &TestNode{name: "a2", size: 60}
+	a := &TestNode{name: "a", children: []*TestNode{a1, a2}, size: 100}
+	b := &TestNode{name: "b", size: 50}
+	root := TestNode{name: "root", children: []*TestNode{a, b}, size: 150}
+
+	canvas := Rect{X: 0, Y: 0, W: 100, H: 100}
+	blocks, _ := Squarify(root, canvas, Options{Algorithm: SliceAndDice})
+
+	byName := map[string]Block{}
+	for _, blk := range blocks {
+		byName[blk.TreeSizer.(*TestNode).name] = blk
+	}
+	aBlk, bBlk, a1Blk, a2Blk := byName["a"], byName["b"], byName["a1"], byName["a2"]
+
+	const eps = 1e-6
+
+	// depth 0 (root's children) splits horizontally, proportional to Size: a gets 100/150 of the
+	// width, b gets 50/150.
+	if !approxEqual(aBlk.W, 100.0/150.0*100, eps) || !approxEqual(aBlk.H, 100, eps) || !approxEqual(aBlk.X, 0, eps) {
+		t.Fatalf("a block = %+v, wanted a horizontal, proportional split", aBlk)
+	}
+	if !approxEqual(bBlk.X, aBlk.X+aBlk.W, eps) || !approxEqual(bBlk.W, 50.0/150.0*100, eps) || !approxEqual(bBlk.H, 100, eps) {
+		t.Fatalf("b block %+v does not sit beside a (ends at %v)", bBlk, aBlk.X+aBlk.W)
+	}
+
+	// depth 1 (a's children) alternates to a vertical split, still proportional to Size.
+	if !approxEqual(a1Blk.W, aBlk.W, eps) || !approxEqual(a1Blk.H, aBlk.H*40.0/100.0, eps) || !approxEqual(a1Blk.Y, aBlk.Y, eps) {
+		t.Fatalf("a1 block = %+v, wanted a vertical, proportional split within a", a1Blk)
+	}
+	if !approxEqual(a2Blk.Y, a1Blk.Y+a1Blk.H, eps) || !approxEqual(a2Blk.W, aBlk.W, eps) {
+		t.Fatalf("a2 block %+v does not sit below a1 (ends at %v)", a2Blk, a1Blk.Y+a1Blk.H)
+	}
+}
+
+// TestStripNeverReordersButBreaksRowsDifferentlyThanSquarified exercises a size distribution where
+// Strip's mean-aspect row-break decision disagrees with Squarified's worst-aspect decision: Squarified
+// gives n2 ("c") its own column, while Strip packs n2 and n3 into the same column and defers breaking
+// until after n3. Despite laying out differently, Strip must still emit blocks in the original order.
+func TestStripNeverReordersButBreaksRowsDifferentlyThanSquarified(t *testing.T) {
+	sizes := []float64{5, 4, 3, 2, 1}
+	nodes := make([]*TestNode, len(sizes))
+	total := float64(0)
+	for i, s := range sizes {
+		nodes[i] = &TestNode{name: string(rune('a' + i)), size: s}
+		total += s
+	}
+	root := TestNode{name: "root", children: nodes, size: total}
+	canvas := Rect{X: 0, Y: 0, W: 100, H: 40}
+
+	squarified, _ := Squarify(root, canvas, Options{Algorithm: Squarified})
+	strip, _ := Squarify(root, canvas, Options{Algorithm: Strip})
+
+	const eps = 1e-2
+
+	byName := func(blocks []Block) map[string]Block {
+		m := map[string]Block{}
+		for _, blk := range blocks {
+			m[blk.TreeSizer.(*TestNode).name] = blk
+		}
+		return m
+	}
+	sq, st := byName(squarified), byName(strip)
+
+	// Squarified gives n2 ("c") a column of its own: its height spans the full canvas.
+	if !approxEqual(sq["c"].H, canvas.H, eps) {
+		t.Fatalf("Squarified c block = %+v, wanted it to span the full height alone", sq["c"])
+	}
+
+	// Strip instead shares a column between "c" and "d" (their heights split the canvas, not the
+	// full height each), committing the row later than Squarified did.
+	if approxEqual(st["c"].H, canvas.H, eps) {
+		t.Fatalf("Strip c block = %+v, wanted it to share a column with d rather than spanning the full height", st["c"])
+	}
+	if !approxEqual(st["c"].X, st["d"].X, eps) {
+		t.Fatalf("Strip c block %+v and d block %+v should share the same column", st["c"], st["d"])
+	}
+
+	// Strip must never reorder: blocks come out in the same order the children were given in.
+	for i, blk := range strip {
+		if blk.TreeSizer.(*TestNode) != nodes[i] {
+			t.Fatalf("Strip blocks[%d] = %v, wanted %v (Strip must preserve input order)", i, blk.TreeSizer, nodes[i])
+		}
+	}
+}
+
+func TestPivotByMiddleChoosesCumulativeHalfSplitAndPreservesOrder(t *testing.T) {
+	children := []area{
+		{Area: 10, TreeSizer: &TestNode{name: "a"}},
+		{Area: 20, TreeSizer: &TestNode{name: "b"}},
+		{Area: 30, TreeSizer: &TestNode{name: "c"}},
+		{Area: 40, TreeSizer: &TestNode{name: "d"}},
+	}
+
+	l := pivotLayout{byMiddle: true}
+	idx := l.pivotIndex(children)
+
+	// Running sums are 10, 30, 60, 100; half of the 100 total is first reached at index 2 ("c").
+	if idx != 2 {
+		t.Fatalf("pivotIndex = %v, wanted 2 (the item where cumulative size first reaches half the total)", idx)
+	}
+
+	nodes := []*TestNode{
+		{name: "a", size: 10},
+		{name: "b", size: 20},
+		{name: "c", size: 30},
+		{name: "d", size: 40},
+	}
+	root := TestNode{name: "root", children: nodes, size: 100}
+	canvas := Rect{X: 0, Y: 0, W: 100, H: 100}
+
+	blocks, _ := Squarify(root, canvas, Options{Algorithm: PivotByMiddle})
+
+	if len(blocks) != len(nodes) {
+		t.Fatalf("got %d blocks, wanted %d", len(blocks), len(nodes))
+	}
+	for i, blk := range blocks {
+		if blk.TreeSizer.(*TestNode) != nodes[i] {
+			t.Fatalf("blocks[%d] = %v, wanted %v (PivotByMiddle must preserve input order)", i, blk.TreeSizer, nodes[i])
+		}
+	}
+}
+
+func TestSortModeAscendingAndCustom(t *testing.T) {
+	a := &TestNode{name: "a", size: 10}
+	b := &TestNode{name: "b", size: 30}
+	c := &TestNode{name: "c", size: 20}
+	root := TestNode{name: "root", children: []*TestNode{a, b, c}, size: 60}
+
+	canvas := Rect{X: 0, Y: 0, W: 100, H: 100}
+
+	namesInOrder := func(blocks []Block) []string {
+		names := make([]string, len(blocks))
+		for i, blk := range blocks {
+			names[i] = blk.TreeSizer.(*TestNode).name
+		}
+		return names
+	}
+
+	// SortSizeAscending should order from smallest to largest.
+	blocks, _ := Squarify(root, canvas, Options{SortMode: SortSizeAscending})
+	got := namesInOrder(blocks)
+	want := []string{"a", "c", "b"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("SortSizeAscending order = %v, wanted %v", got, want)
+		}
+	}
+
+	// SortCustom with a Less that compares names alphabetically.
+	blocks, _ = Squarify(root, canvas, Options{
+		SortMode: SortCustom,
+		Less: func(x, y TreeSizer) bool {
+			return x.(*TestNode).name < y.(*TestNode).name
+		},
+	})
+	got = namesInOrder(blocks)
+	want = []string{"a", "b", "c"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("SortCustom order = %v, wanted %v", got, want)
+		}
+	}
+}
+
+func TestSortCustomWithNilLess(t *testing.T) {
+	a := &TestNode{name: "a", size: 10}
+	b := &TestNode{name: "b", size: 30}
+	root := TestNode{name: "root", children: []*TestNode{a, b}, size: 40}
+
+	canvas := Rect{X: 0, Y: 0, W: 100, H: 100}
+
+	// SortCustom without a Less must not panic; it should leave children in their original order.
+	blocks, _ := Squarify(root, canvas, Options{SortMode: SortCustom})
+	if len(blocks) != 2 || blocks[0].TreeSizer.(*TestNode) != a || blocks[1].TreeSizer.(*TestNode) != b {
+		t.Fatalf("SortCustom with nil Less = %v, wanted original order [a b]", blocks)
+	}
+}
+
+func TestSquarifyCushions(t *testing.T) {
+	b := &TestNode{name: "b", size: 10}
+	c := &TestNode{name: "c", size: 20}
+	root := TestNode{name: "root", children: []*TestNode{b, c}, size: 30}
+
+	canvas := Rect{X: 0, Y: 0, W: 100, H: 100}
+
+	blocks, _, cushions := SquarifyCushions(root, canvas, Options{Sort: DoSort}, CushionOptions{})
+
+	if len(cushions) != len(blocks) {
+		t.Fatalf("got %d cushions for %d blocks, wanted one each", len(cushions), len(blocks))
+	}
+
+	for i, blk := range blocks {
+		cu := cushions[i]
+		if cu.SX2 >= 0 || cu.SY2 >= 0 {
+			t.Fatalf("block %d: expected a downward-curving cushion (SX2, SY2 < 0), got %+v", i, cu)
+		}
+
+		// The cushion peaks where its normal is straight up, i.e. where 2*SX2*x+SX1 == 0 and
+		// 2*SY2*y+SY1 == 0. Near the light direction used here (mostly straight up), shading right
+		// at the peak should be brighter than shading a few pixels off to the side.
+		peakX, peakY := -cu.SX1/(2*cu.SX2), -cu.SY1/(2*cu.SY2)
+		peak := Shade(cu, peakX, peakY, DefaultLight)
+		off := Shade(cu, peakX+blk.W/4, peakY+blk.H/4, DefaultLight)
+
+		if peak <= off {
+			t.Fatalf("block %d: expected shading at the cushion's peak (%v) to be brighter than shading off to the side (%v)", i, peak, off)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Package squarifyjson loads a nested JSON document into a tree that implements squarify.TreeSizer,
+// so that disk-usage dumps, profiler output, or hand-authored fixtures in the common d3-hierarchy /
+// treemap JSON shape can be fed into squarify.Squarify without writing a TreeSizer adapter by hand.
+//
+// The expected JSON shape is:
+//
+//	{"name": "...", "size": 123, "children": [...]}
+package squarifyjson
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/jeffwilliams/squarify"
+)
+
+// JSONNode implements squarify.TreeSizer over a node of the loaded JSON tree.
+type JSONNode struct {
+	name     string
+	size     float64
+	children []*JSONNode
+
+	// Attrs holds any JSON fields besides "name", "size", and "children", so renderers can pull
+	// labels, colors, or tooltips out of the source document.
+	Attrs map[string]interface{}
+}
+
+// Size implements squarify.TreeSizer. If the source JSON gave the node an explicit size alongside
+// children, that explicit size is returned as-is: like Squarify itself, this package doesn't require
+// a parent's size to equal the sum of its children's, the difference becomes a placeholder area.
+func (n *JSONNode) Size() float64 {
+	return n.size
+}
+
+// NumChildren implements squarify.TreeSizer.
+func (n *JSONNode) NumChildren() int {
+	return len(n.children)
+}
+
+// Child implements squarify.TreeSizer.
+func (n *JSONNode) Child(i int) squarify.TreeSizer {
+	return n.children[i]
+}
+
+// Name returns the node's "name" field.
+func (n *JSONNode) Name() string {
+	return n.name
+}
+
+// rawNode is the shape used to unmarshal a single level of the JSON tree; Children is left as
+// json.RawMessage so each child can be unmarshalled recursively into a JSONNode.
+type rawNode struct {
+	Name     string            `json:"name"`
+	Size     float64           `json:"size"`
+	Children []json.RawMessage `json:"children"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It populates name, size, and children from the well
+// known fields, and collects every other field present in data into Attrs.
+func (n *JSONNode) UnmarshalJSON(data []byte) error {
+	var raw rawNode
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	attrs := map[string]interface{}{}
+	if err := json.Unmarshal(data, &attrs); err != nil {
+		return err
+	}
+	delete(attrs, "name")
+	delete(attrs, "size")
+	delete(attrs, "children")
+
+	n.name = raw.Name
+	n.size = raw.Size
+	n.Attrs = attrs
+
+	n.children = make([]*JSONNode, len(raw.Children))
+	for i, c := range raw.Children {
+		child := &JSONNode{}
+		if err := child.UnmarshalJSON(c); err != nil {
+			return err
+		}
+		n.children[i] = child
+	}
+
+	return nil
+}
+
+// Load reads a JSON tree from r into a *JSONNode.
+func Load(r io.Reader) (*JSONNode, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &JSONNode{}
+	if err := json.Unmarshal(data, root); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// LoadFile reads a JSON tree from the file at path into a *JSONNode.
+func LoadFile(path string) (*JSONNode, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
@@ -0,0 +1,94 @@
+package squarifyjson
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jeffwilliams/squarify"
+)
+
+const sampleJSON = `
+{
+	"name": "root",
+	"size": 100,
+	"color": "gray",
+	"children": [
+		{"name": "a", "size": 40, "color": "red"},
+		{"name": "b", "size": 20, "children": [
+			{"name": "b1", "size": 15},
+			{"name": "b2", "size": 5}
+		]}
+	]
+}`
+
+func TestLoad(t *testing.T) {
+	root, err := Load(strings.NewReader(sampleJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if root.Name() != "root" || root.Size() != 100 {
+		t.Fatalf("root = %+v, wanted name %q size %v", root, "root", 100.0)
+	}
+	if root.Attrs["color"] != "gray" {
+		t.Fatalf("root.Attrs[\"color\"] = %v, wanted \"gray\"", root.Attrs["color"])
+	}
+	if root.NumChildren() != 2 {
+		t.Fatalf("root.NumChildren() = %v, wanted 2", root.NumChildren())
+	}
+
+	a := root.Child(0).(*JSONNode)
+	if a.Name() != "a" || a.Size() != 40 || a.Attrs["color"] != "red" {
+		t.Fatalf("root.Child(0) = %+v, wanted name a, size 40, color red", a)
+	}
+
+	b := root.Child(1).(*JSONNode)
+	if b.NumChildren() != 2 {
+		t.Fatalf("b.NumChildren() = %v, wanted 2", b.NumChildren())
+	}
+	if b.Child(0).(*JSONNode).Name() != "b1" || b.Child(1).(*JSONNode).Name() != "b2" {
+		t.Fatal("b's children were not loaded in order")
+	}
+}
+
+func TestLoadIntoSquarify(t *testing.T) {
+	root, err := Load(strings.NewReader(sampleJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	canvas := squarify.Rect{X: 0, Y: 0, W: 100, H: 100}
+	blocks, _ := squarify.Squarify(root, canvas, squarify.Options{Sort: squarify.DoSort})
+
+	names := map[string]bool{}
+	for _, blk := range blocks {
+		names[blk.TreeSizer.(*JSONNode).Name()] = true
+	}
+	for _, want := range []string{"a", "b", "b1", "b2"} {
+		if !names[want] {
+			t.Fatalf("blocks %v are missing a block for %q", names, want)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "squarifyjson-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(sampleJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := LoadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Name() != "root" {
+		t.Fatalf("LoadFile root.Name() = %q, wanted %q", root.Name(), "root")
+	}
+}
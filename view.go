@@ -0,0 +1,167 @@
+package squarify
+
+// View wraps a root TreeSizer together with a stack of drill-down selections, so that callers
+// building interactive treemap browsers (zooming in on a clicked Block, then navigating back out via
+// breadcrumbs) don't have to re-derive the child subtree themselves.
+type View struct {
+	stack []TreeSizer // stack[0] is the original root; stack[len(stack)-1] is the current render root.
+}
+
+// NewView returns a View rooted at root.
+func NewView(root TreeSizer) *View {
+	return &View{stack: []TreeSizer{root}}
+}
+
+// Zoom descends into block, making its TreeSizer the new render root. block would typically be one
+// of the Blocks returned by a prior call to Render. Zoom is a no-op if block has no TreeSizer (i.e.
+// it's a placeholder for unaccounted space).
+func (v *View) Zoom(block Block) {
+	if block.TreeSizer == nil {
+		return
+	}
+	v.stack = append(v.stack, block.TreeSizer)
+}
+
+// Pop walks back up one level, undoing the most recent Zoom. It is a no-op if the View is already at
+// its original root.
+func (v *View) Pop() {
+	if len(v.stack) > 1 {
+		v.stack = v.stack[:len(v.stack)-1]
+	}
+}
+
+// Breadcrumbs returns the path of TreeSizers from the original root to the current render root, for
+// rendering a breadcrumb trail in a header. Breadcrumbs()[0] is always the original root.
+func (v *View) Breadcrumbs() []TreeSizer {
+	return append([]TreeSizer(nil), v.stack...)
+}
+
+// Render lays out the current render root (the original root, or the TreeSizer last Zoomed into)
+// inside canvas using opts, exactly as Squarify would. If opts.FocusPath is non-empty, the descendant
+// it names is instead given the bulk of canvas regardless of its Size relative to its siblings, for a
+// "focus+context" view.
+func (v *View) Render(canvas Rect, opts Options) ([]Block, []Meta) {
+	current := v.stack[len(v.stack)-1]
+
+	if len(opts.FocusPath) > 0 {
+		if opts.MaxDepth <= 0 {
+			opts.MaxDepth = 20
+		}
+		return focusLayout(current, Block{Rect: canvas}, opts, 0, opts.FocusPath)
+	}
+
+	return Squarify(current, canvas, opts)
+}
+
+// focusFraction is the proportion of a rectangle's area given to the child named by the next element
+// of a FocusPath; the rest is shared among its siblings.
+const focusFraction = 0.8
+
+// focusLayout lays out root like squarify, except that at each level the child named by the next
+// element of path is given focusFraction of block's area instead of its size-proportional share. Once
+// path is exhausted (or no longer names an existing child), the remaining subtree is laid out with
+// the ordinary Squarify algorithm.
+func focusLayout(root TreeSizer, block Block, opts Options, depth int, path []int) (blocks []Block, meta []Meta) {
+	blocks = make([]Block, 0)
+	meta = make([]Meta, 0)
+
+	if block.W <= opts.MinW || block.H <= opts.MinH || depth >= opts.MaxDepth {
+		return
+	}
+
+	n := root.NumChildren()
+	if len(path) == 0 || path[0] < 0 || path[0] >= n {
+		return squarify(root, block, opts, depth)
+	}
+
+	focusIdx := path[0]
+
+	total := float64(0)
+	for i := 0; i < n; i++ {
+		if s := root.Child(i).Size(); s > 0 {
+			total += s
+		}
+	}
+	if total <= 0 {
+		return
+	}
+
+	focusRect, restRect, vertical := splitForFocus(block.Rect, focusFraction)
+
+	focusChild := root.Child(focusIdx)
+	blocks = append(blocks, Block{Rect: focusRect, TreeSizer: focusChild})
+	meta = append(meta, Meta{Depth: depth})
+
+	childRect := focusRect
+	if opts.Margins != nil {
+		childRect.X += opts.Margins.L
+		childRect.Y += opts.Margins.T
+		childRect.W -= opts.Margins.L + opts.Margins.R
+		childRect.H -= opts.Margins.T + opts.Margins.B
+	}
+
+	childBlocks, childMeta := focusLayout(focusChild, Block{Rect: childRect}, opts, depth+1, path[1:])
+	blocks = append(blocks, childBlocks...)
+	meta = append(meta, childMeta...)
+
+	// Lay out the remaining siblings, in order, sharing restRect proportional to their own Size.
+	restTotal := total - focusChild.Size()
+	pos := restRect
+	for i := 0; i < n; i++ {
+		if i == focusIdx {
+			continue
+		}
+
+		child := root.Child(i)
+		if child.Size() <= 0 || restTotal <= 0 {
+			continue
+		}
+
+		frac := child.Size() / restTotal
+		var childRect Rect
+		if vertical {
+			h := pos.H * frac
+			childRect = Rect{X: pos.X, Y: pos.Y, W: pos.W, H: h}
+			pos.Y += h
+			pos.H -= h
+		} else {
+			w := pos.W * frac
+			childRect = Rect{X: pos.X, Y: pos.Y, W: w, H: pos.H}
+			pos.X += w
+			pos.W -= w
+		}
+
+		blocks = append(blocks, Block{Rect: childRect, TreeSizer: child})
+		meta = append(meta, Meta{Depth: depth})
+
+		subRect := childRect
+		if opts.Margins != nil {
+			subRect.X += opts.Margins.L
+			subRect.Y += opts.Margins.T
+			subRect.W -= opts.Margins.L + opts.Margins.R
+			subRect.H -= opts.Margins.T + opts.Margins.B
+		}
+
+		subBlocks, subMeta := squarify(child, Block{Rect: subRect}, opts, depth+1)
+		blocks = append(blocks, subBlocks...)
+		meta = append(meta, subMeta...)
+	}
+
+	return
+}
+
+// splitForFocus splits rect along its longer axis into a focus rectangle occupying frac of rect, and
+// a rest rectangle occupying the remainder, for the siblings of the focused child.
+func splitForFocus(rect Rect, frac float64) (focus, rest Rect, vertical bool) {
+	vertical = rect.H >= rect.W
+	if vertical {
+		h := rect.H * frac
+		focus = Rect{X: rect.X, Y: rect.Y, W: rect.W, H: h}
+		rest = Rect{X: rect.X, Y: rect.Y + h, W: rect.W, H: rect.H - h}
+	} else {
+		w := rect.W * frac
+		focus = Rect{X: rect.X, Y: rect.Y, W: w, H: rect.H}
+		rest = Rect{X: rect.X + w, Y: rect.Y, W: rect.W - w, H: rect.H}
+	}
+	return
+}
@@ -0,0 +1,109 @@
+package squarify
+
+import "testing"
+
+func TestViewZoomAndPop(t *testing.T) {
+	leaf := &TestNode{name: "leaf", size: 5}
+	inner := &TestNode{name: "inner", children: []*TestNode{leaf}, size: 5}
+	root := &TestNode{name: "root", children: []*TestNode{inner}, size: 5}
+
+	v := NewView(root)
+
+	breadcrumbs := v.Breadcrumbs()
+	if len(breadcrumbs) != 1 || breadcrumbs[0].(*TestNode) != root {
+		t.Fatalf("Breadcrumbs before any Zoom = %v, wanted [root]", breadcrumbs)
+	}
+
+	canvas := Rect{X: 0, Y: 0, W: 100, H: 100}
+	blocks, _ := v.Render(canvas, Options{})
+	if len(blocks) != 2 || blocks[0].TreeSizer.(*TestNode) != inner || blocks[1].TreeSizer.(*TestNode) != leaf {
+		t.Fatalf("Render at root produced %v, wanted blocks for [inner leaf]", blocks)
+	}
+
+	v.Zoom(blocks[0])
+
+	breadcrumbs = v.Breadcrumbs()
+	if len(breadcrumbs) != 2 || breadcrumbs[1].(*TestNode) != inner {
+		t.Fatalf("Breadcrumbs after Zoom = %v, wanted [root inner]", breadcrumbs)
+	}
+
+	blocks, _ = v.Render(canvas, Options{})
+	if len(blocks) != 1 || blocks[0].TreeSizer.(*TestNode) != leaf {
+		t.Fatalf("Render after zooming into inner produced %v, wanted a single block for leaf", blocks)
+	}
+
+	v.Pop()
+	if len(v.Breadcrumbs()) != 1 {
+		t.Fatal("Pop did not walk back up to the root")
+	}
+
+	// Pop at the root is a no-op.
+	v.Pop()
+	if len(v.Breadcrumbs()) != 1 {
+		t.Fatal("Pop at the root should be a no-op")
+	}
+}
+
+func TestViewRenderFocusPath(t *testing.T) {
+	a := &TestNode{name: "a", size: 10}
+	b := &TestNode{name: "b", size: 80}
+	c := &TestNode{name: "c", size: 10}
+	root := TestNode{name: "root", children: []*TestNode{a, b, c}, size: 100}
+
+	v := NewView(root)
+	canvas := Rect{X: 0, Y: 0, W: 100, H: 100}
+
+	// Without FocusPath, the largest child ("b") naturally gets most of the area.
+	blocks, _ := v.Render(canvas, Options{})
+	areaOf := func(blocks []Block, name string) float64 {
+		for _, blk := range blocks {
+			if blk.TreeSizer.(*TestNode).name == name {
+				return blk.W * blk.H
+			}
+		}
+		return 0
+	}
+	if areaOf(blocks, "a") >= areaOf(blocks, "b") {
+		t.Fatal("expected b to get more area than a without a FocusPath")
+	}
+
+	// With FocusPath pointing at "a" (index 0), it should dominate instead, even though it's the
+	// smallest child.
+	blocks, _ = v.Render(canvas, Options{FocusPath: []int{0}})
+	if areaOf(blocks, "a") <= areaOf(blocks, "b") {
+		t.Fatal("expected FocusPath to give a more area than b")
+	}
+}
+
+func TestViewRenderFocusPathHonorsMargins(t *testing.T) {
+	leaf := &TestNode{name: "leaf", size: 5}
+	focused := &TestNode{name: "focused", children: []*TestNode{leaf}, size: 5}
+	other := &TestNode{name: "other", size: 5}
+	root := TestNode{name: "root", children: []*TestNode{focused, other}, size: 10}
+
+	v := NewView(root)
+	canvas := Rect{X: 0, Y: 0, W: 100, H: 100}
+	margins := &Margins{L: 2, R: 2, T: 2, B: 2}
+
+	blocks, _ := v.Render(canvas, Options{FocusPath: []int{0}, Margins: margins})
+
+	var focusedRect, leafRect Rect
+	for _, blk := range blocks {
+		switch blk.TreeSizer.(*TestNode) {
+		case focused:
+			focusedRect = blk.Rect
+		case leaf:
+			leafRect = blk.Rect
+		}
+	}
+
+	if leafRect.X < focusedRect.X+margins.L || leafRect.Y < focusedRect.Y+margins.T {
+		t.Fatalf("leaf rect %+v is not inset from focused rect %+v by Margins", leafRect, focusedRect)
+	}
+	if leafRect.X+leafRect.W > focusedRect.X+focusedRect.W-margins.R {
+		t.Fatalf("leaf rect %+v is not inset from focused rect's right edge by Margins", leafRect)
+	}
+	if leafRect.Y+leafRect.H > focusedRect.Y+focusedRect.H-margins.B {
+		t.Fatalf("leaf rect %+v is not inset from focused rect's bottom edge by Margins", leafRect)
+	}
+}